@@ -0,0 +1,195 @@
+// Package build holds the internal (hub) representation of the build API
+// types consumed by pkg/build/apiserver. Only the subset of fields actually
+// read or written by that package is modeled here; the versioned wire
+// format lives in the vendored github.com/openshift/api/build/v1 package.
+package build
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// BuildTriggerCauseManualMsg is recorded on a BuildRequest that wasn't
+// triggered by a webhook, image change, or config change.
+const BuildTriggerCauseManualMsg = "Manually triggered"
+
+// Resource returns a GroupResource for the build API group, used to build
+// NotFound/Conflict errors for build-related subresources.
+func Resource(resource string) schema.GroupResource {
+	return schema.GroupResource{Group: "build.openshift.io", Resource: resource}
+}
+
+// BuildTriggerCause records why a Build was instantiated.
+type BuildTriggerCause struct {
+	Message string
+}
+
+// SourceControlUser identifies a commit's author or committer.
+type SourceControlUser struct {
+	Name  string
+	Email string
+}
+
+// GitSourceRevision is the VCS revision a Build was generated from.
+type GitSourceRevision struct {
+	Committer SourceControlUser
+	Author    SourceControlUser
+	Message   string
+	Commit    string
+}
+
+// SourceRevision wraps the VCS-specific revision info for a Build.
+type SourceRevision struct {
+	Git *GitSourceRevision
+}
+
+// BinaryBuildSource marks a Build as fed by an uploaded binary archive
+// rather than (or in addition to) a Git checkout.
+type BinaryBuildSource struct {
+	AsFile string
+}
+
+// GitBuildSource is the Git repository a BuildConfig clones from.
+type GitBuildSource struct {
+	URI string
+	Ref string
+}
+
+// BuildSource is the union of source inputs a Build can have. A BuildConfig
+// with no Git source configured (source.Git == nil) is expected to be fed
+// entirely through BinaryBuildSource uploads.
+type BuildSource struct {
+	Git    *GitBuildSource
+	Binary *BinaryBuildSource
+}
+
+// CustomBuildStrategy marks a Build as using a custom builder image rather
+// than one of the standard strategies.
+type CustomBuildStrategy struct {
+	From string
+}
+
+// BuildStrategy is the union of strategies a Build can use.
+type BuildStrategy struct {
+	CustomStrategy *CustomBuildStrategy
+}
+
+// BuildSpec is the input to a Build.
+type BuildSpec struct {
+	Source   BuildSource
+	Strategy BuildStrategy
+}
+
+// BuildStatus carries the minimal run-time state this package inspects
+// before it attaches to a build pod.
+type BuildStatus struct {
+	Cancelled bool
+	Message   string
+	Reason    string
+}
+
+// Build is the internal representation of a running or completed build.
+type Build struct {
+	Namespace string
+	Name      string
+	Spec      BuildSpec
+	Status    BuildStatus
+}
+
+// BuildRequest is posted to InstantiateREST (or BinaryInstantiateREST, which
+// synthesizes one) to start a new Build from a BuildConfig.
+type BuildRequest struct {
+	metav1.TypeMeta
+
+	Name        string
+	Namespace   string
+	Revision    *SourceRevision
+	Binary      *BinaryBuildSource
+	TriggeredBy []BuildTriggerCause
+
+	// Async requests that Create return immediately with a
+	// buildInstantiateStatus token instead of blocking until the Build
+	// object is created.
+	Async bool
+}
+
+func (r *BuildRequest) GetObjectKind() schema.ObjectKind { return &r.TypeMeta }
+func (r *BuildRequest) DeepCopyObject() runtime.Object {
+	out := *r
+	if r.Revision != nil {
+		rev := *r.Revision
+		if r.Revision.Git != nil {
+			git := *r.Revision.Git
+			rev.Git = &git
+		}
+		out.Revision = &rev
+	}
+	if r.Binary != nil {
+		bin := *r.Binary
+		out.Binary = &bin
+	}
+	if r.TriggeredBy != nil {
+		out.TriggeredBy = append([]BuildTriggerCause(nil), r.TriggeredBy...)
+	}
+	return &out
+}
+
+// BinaryBuildRequestOptions carries the query parameters accepted on the
+// `instantiatebinary` connector.
+type BinaryBuildRequestOptions struct {
+	metav1.TypeMeta
+
+	Name string
+
+	AsFile string
+
+	Commit         string
+	Message        string
+	CommitterName  string
+	CommitterEmail string
+	AuthorName     string
+	AuthorEmail    string
+
+	// NoSource indicates the target BuildConfig has no Git/VCS source
+	// defined (or the caller explicitly wants a binary-only build) and the
+	// uploaded archive is the entire build context, so a dummy commit isn't
+	// required to satisfy the usual git-clone flow. Validation reads it only
+	// to reject a Commit alongside it; the container that receives the
+	// archive is chosen from the Build's own source, not from this field.
+	NoSource bool
+}
+
+func (o *BinaryBuildRequestOptions) GetObjectKind() schema.ObjectKind { return &o.TypeMeta }
+func (o *BinaryBuildRequestOptions) DeepCopyObject() runtime.Object {
+	out := *o
+	return &out
+}
+
+// BuildCancelRequest is posted to the `cancel` subresource of a Build to
+// mark it cancelled and wait for the build controller to tear down its pod.
+type BuildCancelRequest struct {
+	metav1.TypeMeta
+
+	Name string
+
+	// Message, when set, replaces the Build's status message in the same
+	// update that marks the Build cancelled.
+	Message string
+
+	// GracePeriodSeconds bounds how long to wait for the cancellation to be
+	// observed before giving up. A value of 0 means "check once, don't
+	// wait" rather than "wait zero seconds" (which would otherwise always
+	// time out before the condition is ever evaluated).
+	GracePeriodSeconds *int64
+}
+
+func (r *BuildCancelRequest) GetObjectKind() schema.ObjectKind { return &r.TypeMeta }
+func (r *BuildCancelRequest) DeepCopyObject() runtime.Object {
+	out := *r
+	if r.GracePeriodSeconds != nil {
+		gp := *r.GracePeriodSeconds
+		out.GracePeriodSeconds = &gp
+	}
+	return &out
+}
@@ -0,0 +1,26 @@
+// Package validation holds validation logic for the build API types that
+// isn't already covered by generic apiserver machinery (required fields,
+// defaulting, etc.), mirroring how other API groups split hand-written
+// semantic validation out of their REST strategies.
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	buildapi "github.com/openshift/origin/pkg/build/apis/build"
+)
+
+// ValidateBinaryBuildRequestOptions validates the query parameters accepted
+// on the `instantiatebinary` connector.
+func ValidateBinaryBuildRequestOptions(options *buildapi.BinaryBuildRequestOptions) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// NoSource means the BuildConfig has no Git/VCS source defined and the
+	// uploaded archive is the entire build context, so there's no revision
+	// for a commit to identify.
+	if options.NoSource && len(options.Commit) > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("commit"), options.Commit, "commit cannot be set on a NoSource binary build request"))
+	}
+
+	return allErrs
+}
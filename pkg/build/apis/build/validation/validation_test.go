@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"testing"
+
+	buildapi "github.com/openshift/origin/pkg/build/apis/build"
+)
+
+func TestValidateBinaryBuildRequestOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *buildapi.BinaryBuildRequestOptions
+		wantErr bool
+	}{
+		{
+			name:    "no source, no commit",
+			options: &buildapi.BinaryBuildRequestOptions{NoSource: true},
+			wantErr: false,
+		},
+		{
+			name:    "git source with commit",
+			options: &buildapi.BinaryBuildRequestOptions{Commit: "abc123"},
+			wantErr: false,
+		},
+		{
+			name:    "no source with commit is invalid",
+			options: &buildapi.BinaryBuildRequestOptions{NoSource: true, Commit: "abc123"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs := ValidateBinaryBuildRequestOptions(test.options)
+			if test.wantErr && len(errs) == 0 {
+				t.Fatal("expected a validation error, got none")
+			}
+			if !test.wantErr && len(errs) > 0 {
+				t.Fatalf("unexpected validation errors: %v", errs)
+			}
+		})
+	}
+}
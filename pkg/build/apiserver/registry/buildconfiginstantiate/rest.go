@@ -2,9 +2,13 @@ package buildconfiginstantiate
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -13,10 +17,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apimachinery/pkg/util/wait"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
 	restclient "k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 	kapi "k8s.io/kubernetes/pkg/apis/core"
 
@@ -35,15 +40,137 @@ var (
 	cancelPollDuration = 30 * time.Second
 )
 
+const (
+	// uploadSessionHeader carries the server-assigned resumable upload
+	// session ID, both on the initial response and on subsequent chunk
+	// POSTs that resume it.
+	uploadSessionHeader = "X-Build-Upload-Session"
+	// uploadOffsetHeader carries the byte offset a chunk POST starts at,
+	// matching the tus.io-style resumable upload convention.
+	uploadOffsetHeader = "Upload-Offset"
+	// uploadCompleteHeader, set to "true", marks the terminal chunk of an
+	// upload, after which the handler attaches to the build pod and streams
+	// the assembled archive. A POST that carries neither this header nor a
+	// session ID is also treated as a complete, single-request upload.
+	uploadCompleteHeader = "X-Build-Upload-Complete"
+
+	// instantiationTokenAnnotation records the async instantiation token on
+	// the Build created on its behalf, so a client can correlate the two.
+	instantiationTokenAnnotation = "build.openshift.io/instantiate-token"
+
+	// asyncDedupeWindow bounds how long a repeated async BuildRequest with
+	// the same dedupe key reuses an in-flight instantiation's token instead
+	// of creating a duplicate Build. This matters for webhook-style trigger
+	// sources that retry aggressively.
+	asyncDedupeWindow = 30 * time.Second
+
+	// pendingInstantiationTTL bounds how long a pendingInstantiation entry
+	// is kept even if nobody ever polls its progress or it outlives the
+	// dedupe window, so abandoned async requests don't accumulate forever.
+	pendingInstantiationTTL = 10 * time.Minute
+)
+
 // NewStorage creates a new storage object for build generation
 func NewStorage(generator *generator.BuildGenerator) *InstantiateREST {
-	return &InstantiateREST{generator: generator}
+	return &InstantiateREST{
+		generator:    generator,
+		dedupeWindow: asyncDedupeWindow,
+		pending:      map[string]*pendingInstantiation{},
+	}
 }
 
 // InstantiateREST is a RESTStorage implementation for a BuildGenerator which supports only
 // the Create operation (as the generator has no underlying storage object).
 type InstantiateREST struct {
 	generator *generator.BuildGenerator
+
+	dedupeWindow time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingInstantiation
+}
+
+// pendingInstantiation tracks an async instantiation that is either still
+// running generator.InstantiateInternal or has already produced a Build.
+// key, token, namespace and created are set before the entry is published
+// and never change afterwards. buildName and err are written later and are
+// guarded by InstantiateREST.mu; go through the accessor methods for those.
+type pendingInstantiation struct {
+	key       string
+	token     string
+	namespace string
+	buildName string
+	err       error
+	created   time.Time
+}
+
+// findPendingByToken returns the pendingInstantiation for a progress-polling
+// token, or nil if it doesn't exist (never existed, already reaped, or the
+// caller got the token wrong).
+func (s *InstantiateREST) findPendingByToken(token string) *pendingInstantiation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.pending {
+		if p.token == token {
+			return p
+		}
+	}
+	return nil
+}
+
+// pendingBuildName safely reads the Build name a pendingInstantiation has
+// produced so far (empty until generator.InstantiateInternal returns).
+func (s *InstantiateREST) pendingBuildName(p *pendingInstantiation) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return p.buildName
+}
+
+// setPendingBuildName safely records the Build name once it's known.
+func (s *InstantiateREST) setPendingBuildName(p *pendingInstantiation, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.buildName = name
+}
+
+// pendingErr safely reads the error generator.InstantiateInternal failed
+// with, or nil if it hasn't failed (yet).
+func (s *InstantiateREST) pendingErr(p *pendingInstantiation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return p.err
+}
+
+// setPendingErr safely records that the background instantiation failed, so
+// a caller polling progress stops waiting on a buildName that will never
+// arrive.
+func (s *InstantiateREST) setPendingErr(p *pendingInstantiation, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.err = err
+}
+
+// forgetPending removes a pendingInstantiation once a progress stream for it
+// has ended (finished, failed, or the client went away), so it doesn't linger
+// until the TTL sweep.
+func (s *InstantiateREST) forgetPending(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, key)
+}
+
+// reapPendingLocked drops entries older than pendingInstantiationTTL. Called
+// with s.mu already held, opportunistically from instantiateAsync, so an
+// apiserver that only ever serves async instantiate traffic doesn't
+// accumulate pendingInstantiation entries (and their goroutines' results)
+// forever for clients that never poll progress.
+func (s *InstantiateREST) reapPendingLocked() {
+	now := time.Now()
+	for key, p := range s.pending {
+		if now.Sub(p.created) > pendingInstantiationTTL {
+			delete(s.pending, key)
+		}
+	}
 }
 
 var _ rest.Creater = &InstantiateREST{}
@@ -72,9 +199,216 @@ func (s *InstantiateREST) Create(ctx context.Context, obj runtime.Object, create
 			},
 		)
 	}
+	if request.Async {
+		return s.instantiateAsync(ctx, request)
+	}
 	return s.generator.InstantiateInternal(ctx, request)
 }
 
+// instantiateAsync kicks off generator.InstantiateInternal in the
+// background and immediately returns a buildInstantiateStatus carrying a
+// token the client can use to poll progress via
+// BuildInstantiateProgressREST, instead of blocking until the Build object
+// exists. A retry of the same BuildRequest (same dedupe key) within
+// dedupeWindow gets back the existing token rather than creating a second
+// Build.
+func (s *InstantiateREST) instantiateAsync(ctx context.Context, request *buildapi.BuildRequest) (runtime.Object, error) {
+	key := instantiateDedupeKey(request)
+
+	s.mu.Lock()
+	s.reapPendingLocked()
+	if existing, ok := s.pending[key]; ok && time.Since(existing.created) < s.dedupeWindow {
+		status := &buildInstantiateStatus{Token: existing.token, BuildName: existing.buildName}
+		s.mu.Unlock()
+		return status, nil
+	}
+	pending := &pendingInstantiation{
+		key:       key,
+		token:     string(uuid.NewUUID()),
+		namespace: request.Namespace,
+		created:   time.Now(),
+	}
+	s.pending[key] = pending
+	s.mu.Unlock()
+
+	// instantiateAsync returns to the client as soon as the goroutine below
+	// is spawned, and request-scoped contexts are typically cancelled once
+	// the HTTP handler that created them returns -- so the background work
+	// this feature exists to decouple from the client connection must not
+	// inherit ctx's cancellation, only the identity/namespace it carries.
+	bgCtx := apirequest.NewContext()
+	if ns, ok := apirequest.NamespaceFrom(ctx); ok {
+		bgCtx = apirequest.WithNamespace(bgCtx, ns)
+	}
+	if user, ok := apirequest.UserFrom(ctx); ok {
+		bgCtx = apirequest.WithUser(bgCtx, user)
+	}
+
+	go func() {
+		build, err := s.generator.InstantiateInternal(bgCtx, request)
+		if err != nil {
+			glog.V(2).Infof("async instantiate %s failed: %v", pending.token, err)
+			s.setPendingErr(pending, err)
+			return
+		}
+
+		s.setPendingBuildName(pending, build.Name)
+
+		versioned := &buildv1.Build{}
+		if err := legacyscheme.Scheme.Convert(build, versioned, nil); err != nil {
+			glog.Errorf("unable to convert build to versioned build: %v", err)
+			return
+		}
+		if versioned.Annotations == nil {
+			versioned.Annotations = map[string]string{}
+		}
+		versioned.Annotations[instantiationTokenAnnotation] = pending.token
+		if _, err := s.generator.Client.UpdateBuild(bgCtx, versioned); err != nil {
+			glog.V(2).Infof("failed to annotate build %s with instantiation token: %v", build.Name, err)
+		}
+	}()
+
+	return &buildInstantiateStatus{Token: pending.token}, nil
+}
+
+// instantiateDedupeKey hashes the parts of a BuildRequest that identify a
+// logical retry of the same trigger (its trigger cause and source revision)
+// so repeated async requests within the dedupe window collapse onto the
+// same pendingInstantiation instead of creating duplicate Builds.
+func instantiateDedupeKey(request *buildapi.BuildRequest) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s/%s", request.Namespace, request.Name)
+	if request.Revision != nil && request.Revision.Git != nil {
+		fmt.Fprintf(h, "|%s", request.Revision.Git.Commit)
+	}
+	for _, cause := range request.TriggeredBy {
+		fmt.Fprintf(h, "|%s", cause.Message)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// buildInstantiateStatus is returned immediately by an async Create; it
+// carries the token a client polls BuildInstantiateProgressREST with, and
+// the Build name once generation has produced one.
+type buildInstantiateStatus struct {
+	metav1.TypeMeta
+
+	Token     string `json:"token"`
+	BuildName string `json:"buildName,omitempty"`
+}
+
+func (s *buildInstantiateStatus) GetObjectKind() schema.ObjectKind { return &s.TypeMeta }
+func (s *buildInstantiateStatus) DeepCopyObject() runtime.Object {
+	out := *s
+	return &out
+}
+
+func init() {
+	// buildInstantiateStatus has to be a known type before the negotiated
+	// serializer can encode it as a Create response, same as every other
+	// API object InstantiateREST/BinaryInstantiateREST hand back.
+	legacyscheme.Scheme.AddKnownTypeWithName(
+		schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "BuildInstantiateStatus"},
+		&buildInstantiateStatus{},
+	)
+}
+
+// NewInstantiateProgressStorage creates storage for polling the progress of
+// an asynchronous BuildRequest instantiation created through instantiate's
+// async mode.
+func NewInstantiateProgressStorage(instantiate *InstantiateREST, buildClient buildtypedclient.BuildsGetter) *BuildInstantiateProgressREST {
+	return &BuildInstantiateProgressREST{Instantiate: instantiate, BuildClient: buildClient}
+}
+
+// BuildInstantiateProgressREST streams instantiation progress events
+// (queued -> generating -> build-created -> pod-scheduled -> running) for a
+// token returned by an async instantiate Create call.
+type BuildInstantiateProgressREST struct {
+	Instantiate *InstantiateREST
+	BuildClient buildtypedclient.BuildsGetter
+}
+
+var _ rest.Connecter = &BuildInstantiateProgressREST{}
+
+// New creates a new build generation request, reused only for option decoding.
+func (r *BuildInstantiateProgressREST) New() runtime.Object {
+	return &buildapi.BuildRequest{}
+}
+
+// Connect streams a chunked JSON progress feed for the instantiation
+// identified by the "token" query parameter.
+func (r *BuildInstantiateProgressREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token := req.URL.Query().Get("token")
+
+		pending := r.Instantiate.findPendingByToken(token)
+		if pending == nil {
+			responder.Error(errors.NewNotFound(buildapi.Resource("buildrequest"), token))
+			return
+		}
+		defer r.Instantiate.forgetPending(pending.key)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+		emit := func(phase string) {
+			fmt.Fprintf(w, `{"phase":%q}`+"\n", phase)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		emit("queued")
+		emit("generating")
+		var buildName string
+		for {
+			if err := r.Instantiate.pendingErr(pending); err != nil {
+				fmt.Fprintf(w, `{"phase":"failed","error":%q}`+"\n", err.Error())
+				if flusher != nil {
+					flusher.Flush()
+				}
+				return
+			}
+			buildName = r.Instantiate.pendingBuildName(pending)
+			if len(buildName) > 0 {
+				break
+			}
+			select {
+			case <-req.Context().Done():
+				return
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+		emit("build-created")
+
+		latest, ok, err := buildwait.WaitForRunningBuild(r.BuildClient, pending.namespace, buildName, 5*time.Minute)
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, `{"phase":"failed","error":%q}`+"\n", err.Error())
+		case !ok:
+			fmt.Fprintf(w, `{"phase":"timeout"}`+"\n")
+		case latest.Status.Phase == buildv1.BuildPhaseRunning:
+			emit("pod-scheduled")
+			emit("running")
+		default:
+			fmt.Fprintf(w, `{"phase":%q}`+"\n", string(latest.Status.Phase))
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}), nil
+}
+
+// NewConnectOptions prepares a request for progress polling.
+func (r *BuildInstantiateProgressREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return &buildapi.BuildRequest{}, false, ""
+}
+
+// ConnectMethods returns GET, the only supported progress method.
+func (r *BuildInstantiateProgressREST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
 func (s *InstantiateREST) ProducesObject(verb string) interface{} {
 	// for documentation purposes
 	return buildv1.Build{}
@@ -84,17 +418,143 @@ func (s *InstantiateREST) ProducesMIMETypes(verb string) []string {
 	return nil // no additional mime types
 }
 
-func NewBinaryStorage(generator *generator.BuildGenerator, buildClient buildtypedclient.BuildsGetter, inClientConfig *restclient.Config) *BinaryInstantiateREST {
+// NewCancelStorage creates a new storage object for the build cancel
+// subresource.
+func NewCancelStorage(generator *generator.BuildGenerator) *BuildCancelREST {
+	return &BuildCancelREST{
+		Generator:    generator,
+		PollInterval: cancelPollInterval,
+		PollDuration: cancelPollDuration,
+	}
+}
+
+// BuildCancelREST implements the `cancel` subresource of a Build, a
+// user-facing way for kubectl/oc to cancel a build without going through a
+// generic Build status update. binaryInstantiateHandler.cancelBuild keeps its
+// own internal loop, which only retries the update on conflict.
+type BuildCancelREST struct {
+	Generator *generator.BuildGenerator
+	// PollInterval and PollDuration default to cancelPollInterval and
+	// cancelPollDuration but can be overridden per-storage, e.g. for tests.
+	PollInterval time.Duration
+	PollDuration time.Duration
+}
+
+var _ rest.Creater = &BuildCancelREST{}
+var _ rest.StorageMetadata = &BuildCancelREST{}
+
+// New creates a new build cancel request.
+func (r *BuildCancelREST) New() runtime.Object {
+	return &buildapi.BuildCancelRequest{}
+}
+
+// Create marks the named Build as cancelled, waits for the build controller
+// to observe the cancellation and tear down associated pods, and returns the
+// final Build object.
+func (r *BuildCancelREST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	request, ok := obj.(*buildapi.BuildCancelRequest)
+	if !ok {
+		return nil, errors.NewBadRequest(fmt.Sprintf("not a BuildCancelRequest: %#v", obj))
+	}
+	if err := createValidation(obj); err != nil {
+		return nil, err
+	}
+
+	build, err := r.Generator.Client.GetBuild(ctx, request.Name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if buildutil.IsBuildComplete(build) {
+		return build, nil
+	}
+
+	build.Status.Cancelled = true
+	if len(request.Message) > 0 {
+		build.Status.Message = request.Message
+	}
+	if err := r.Generator.Client.UpdateBuild(ctx, build); err != nil {
+		return nil, err
+	}
+
+	var final *buildv1.Build
+	condition := func() (bool, error) {
+		latest, err := r.Generator.Client.GetBuild(ctx, request.Name, &metav1.GetOptions{})
+		switch {
+		case err != nil && errors.IsConflict(err):
+			build = latest
+			build.Status.Cancelled = true
+			return false, r.Generator.Client.UpdateBuild(ctx, build)
+		case err != nil:
+			return false, err
+		case !buildutil.IsBuildComplete(latest):
+			// Status.Cancelled reads back true as soon as our own update
+			// lands; only a terminal phase shows the controller has acted.
+			return false, nil
+		default:
+			final = latest
+			return true, nil
+		}
+	}
+
+	// A GracePeriodSeconds of 0 (or negative, which is just as meaningless a
+	// duration to wait) means "check once, don't wait" rather than "wait
+	// zero/negative seconds" -- wait.Poll with a non-positive duration never
+	// gives the condition a chance to run and always returns
+	// ErrWaitTimeout, which would misreport an already-cancelled build as a
+	// timeout.
+	if request.GracePeriodSeconds != nil && *request.GracePeriodSeconds <= 0 {
+		done, err := condition()
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		if !done {
+			return nil, errors.NewTimeoutError(fmt.Sprintf("build %s was not yet cancelled", request.Name), 0)
+		}
+		return final, nil
+	}
+
+	pollDuration := r.PollDuration
+	if request.GracePeriodSeconds != nil {
+		pollDuration = time.Duration(*request.GracePeriodSeconds) * time.Second
+	}
+
+	if err := wait.Poll(r.PollInterval, pollDuration, condition); err != nil {
+		return nil, errors.NewInternalError(fmt.Errorf("timed out waiting for build %s to be cancelled: %v", request.Name, err))
+	}
+	return final, nil
+}
+
+func (r *BuildCancelREST) ProducesObject(verb string) interface{} {
+	// for documentation purposes
+	return buildv1.Build{}
+}
+
+func (r *BuildCancelREST) ProducesMIMETypes(verb string) []string {
+	return nil // no additional mime types
+}
+
+// NewBinaryStorage creates a new storage object for binary build uploads.
+// transport selects how the assembled archive is delivered to the build pod;
+// passing nil defaults to SPDYUploadTransport, the historical behavior.
+// Callers that need an alternative -- e.g. WebSocketUploadTransport for
+// front-ends that throttle or reject SPDY -- pass it in explicitly.
+func NewBinaryStorage(generator *generator.BuildGenerator, buildClient buildtypedclient.BuildsGetter, inClientConfig *restclient.Config, transport UploadTransport) *BinaryInstantiateREST {
 	clientConfig := restclient.CopyConfig(inClientConfig)
 	clientConfig.APIPath = "/api"
 	clientConfig.GroupVersion = &schema.GroupVersion{Version: "v1"}
 	clientConfig.NegotiatedSerializer = legacyscheme.Codecs
 
+	if transport == nil {
+		transport = SPDYUploadTransport{}
+	}
+
 	return &BinaryInstantiateREST{
 		Generator:    generator,
 		BuildClient:  buildClient,
 		ClientConfig: clientConfig,
 		Timeout:      5 * time.Minute,
+		Sessions:     newUploadSessionRegistry(),
+		Transport:    transport,
 	}
 }
 
@@ -103,6 +563,13 @@ type BinaryInstantiateREST struct {
 	BuildClient  buildtypedclient.BuildsGetter
 	ClientConfig *restclient.Config
 	Timeout      time.Duration
+	// Sessions tracks in-flight resumable uploads so a dropped connection
+	// can resume from the last acknowledged offset instead of restarting.
+	Sessions *uploadSessionRegistry
+	// Transport delivers the assembled archive to the build pod. It
+	// defaults to SPDYUploadTransport but can be swapped for
+	// WebSocketUploadTransport, HTTP2UploadTransport, or a test fake.
+	Transport UploadTransport
 }
 
 var _ rest.Connecter = &BinaryInstantiateREST{}
@@ -143,6 +610,54 @@ func (r *BinaryInstantiateREST) ProducesMIMETypes(verb string) []string {
 	return nil // no additional mime types
 }
 
+// NewBinaryStatusStorage creates a storage object for polling the progress
+// of a resumable binary upload session created by BinaryInstantiateREST.
+func NewBinaryStatusStorage(binary *BinaryInstantiateREST) *BinaryInstantiateStatusREST {
+	return &BinaryInstantiateStatusREST{Sessions: binary.Sessions}
+}
+
+// BinaryInstantiateStatusREST serves the `instantiatebinary/{name}/status`
+// subresource, letting a client check how many bytes of a resumable upload
+// have been acknowledged without having to resend them.
+type BinaryInstantiateStatusREST struct {
+	Sessions *uploadSessionRegistry
+}
+
+var _ rest.Connecter = &BinaryInstantiateStatusREST{}
+
+// New creates a new binary build request, reused only for option decoding.
+func (r *BinaryInstantiateStatusREST) New() runtime.Object {
+	return &buildapi.BinaryBuildRequestOptions{}
+}
+
+// Connect returns a handler that reports the state of the upload session
+// named by the X-Build-Upload-Session header or "session" query parameter.
+func (r *BinaryInstantiateStatusREST) Connect(ctx context.Context, name string, options runtime.Object, responder rest.Responder) (http.Handler, error) {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sessionID := req.Header.Get(uploadSessionHeader)
+		if len(sessionID) == 0 {
+			sessionID = req.URL.Query().Get("session")
+		}
+		session, ok := r.Sessions.Get(sessionID)
+		if !ok {
+			responder.Error(errors.NewNotFound(buildapi.Resource("binarybuildrequest"), sessionID))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":%q,"bytesReceived":%d,"checksum":%q}`, session.ID, session.Received, session.Checksum())
+	}), nil
+}
+
+// NewConnectOptions prepares a binary build request for status polling.
+func (r *BinaryInstantiateStatusREST) NewConnectOptions() (runtime.Object, bool, string) {
+	return &buildapi.BinaryBuildRequestOptions{}, false, ""
+}
+
+// ConnectMethods returns GET, the only supported status method.
+func (r *BinaryInstantiateStatusREST) ConnectMethods() []string {
+	return []string{"GET"}
+}
+
 // binaryInstantiateHandler responds to upload requests
 type binaryInstantiateHandler struct {
 	r *BinaryInstantiateREST
@@ -157,14 +672,81 @@ var _ http.Handler = &binaryInstantiateHandler{}
 
 func (h *binaryInstantiateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
-	build, err := h.handle(r.Body)
+	session, build, err := h.handleChunk(r)
 	if err != nil {
 		h.responder.Error(err)
 		return
 	}
+	if build == nil {
+		// More chunks are expected; tell the client which session to resume
+		// and how many bytes we've acknowledged so far.
+		w.Header().Set(uploadSessionHeader, session.ID)
+		w.Header().Set(uploadOffsetHeader, strconv.FormatInt(session.Received, 10))
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
 	h.responder.Object(http.StatusCreated, build)
 }
 
+// handleChunk appends the request body to the upload session it belongs to
+// (creating one if the client didn't provide a session ID) and, once the
+// client marks the chunk as terminal, hands the assembled archive off to
+// handle for build-pod instantiation and streaming.
+func (h *binaryInstantiateHandler) handleChunk(r *http.Request) (*uploadSession, runtime.Object, error) {
+	sessionID := r.Header.Get(uploadSessionHeader)
+	session, ok := h.r.Sessions.Get(sessionID)
+	if !ok {
+		var err error
+		session, err = h.r.Sessions.New()
+		if err != nil {
+			return nil, nil, errors.NewInternalError(err)
+		}
+	}
+
+	offset := session.Received
+	if raw := r.Header.Get(uploadOffsetHeader); len(raw) > 0 {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, nil, errors.NewBadRequest(fmt.Sprintf("invalid %s header: %v", uploadOffsetHeader, err))
+		}
+		offset = parsed
+	}
+
+	chunk, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, errors.NewInternalError(err)
+	}
+	if _, err := session.Write(offset, chunk); err != nil {
+		return session, nil, errors.NewConflict(buildapi.Resource("binarybuildrequest"), h.name, err)
+	}
+
+	// Every pre-existing binary-build client (oc start-build --from-dir,
+	// --from-file, etc.) POSTs the whole archive in a single request with
+	// neither header set, and expects that single POST to finish the build.
+	// So a chunk is terminal unless the caller is plainly mid-upload: it
+	// already has a session (meaning it received one from an earlier
+	// response) and hasn't said it's done.
+	terminal := true
+	if v := r.Header.Get(uploadCompleteHeader); len(v) > 0 {
+		terminal = v == "true"
+	} else if len(sessionID) > 0 {
+		terminal = false
+	}
+	if !terminal {
+		return session, nil, nil
+	}
+
+	defer h.r.Sessions.Delete(session.ID)
+	archive, err := session.Reader()
+	if err != nil {
+		return session, nil, errors.NewInternalError(err)
+	}
+	defer archive.Close()
+
+	build, err := h.handle(archive)
+	return session, build, err
+}
+
 func (h *binaryInstantiateHandler) handle(r io.Reader) (runtime.Object, error) {
 	h.options.Name = h.name
 	if err := rest.BeforeCreate(BinaryStrategy, h.ctx, h.options); err != nil {
@@ -172,6 +754,10 @@ func (h *binaryInstantiateHandler) handle(r io.Reader) (runtime.Object, error) {
 		return nil, err
 	}
 
+	// BinaryStrategy.Validate (run by BeforeCreate above) already rejected
+	// NoSource combined with a commit, so by this point the two are known
+	// mutually exclusive.
+
 	request := &buildapi.BuildRequest{}
 	request.Name = h.name
 	if len(h.options.Commit) > 0 {
@@ -257,33 +843,20 @@ func (h *binaryInstantiateHandler) handle(r io.Reader) (runtime.Object, error) {
 		Stdin:     true,
 		Container: buildstrategy.GitCloneContainer,
 	}
-	// Custom builds don't have a gitclone container, so we inject the source
-	// directly into the main container.
-	if build.Spec.Strategy.CustomStrategy != nil {
+	switch {
+	case build.Spec.Strategy.CustomStrategy != nil:
+		// Custom builds don't have a git-clone container, so we inject the
+		// source directly into the main container.
 		opts.Container = buildstrategy.CustomBuild
+	case build.Spec.Source.Git == nil:
+		// No Git source means the pod has no git-clone init container to
+		// attach to at all; SourceInjectContainer is the alternate init
+		// container builder pods run instead to receive a binary-only
+		// build context onto the shared build volume.
+		opts.Container = buildstrategy.SourceInjectContainer
 	}
 
-	restClient, err := restclient.RESTClientFor(h.r.ClientConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	// TODO: consider abstracting into a client invocation or client helper
-	req := restClient.Post().
-		Resource("pods").
-		Name(buildPodName).
-		Namespace(build.Namespace).
-		SubResource("attach")
-	req.VersionedParams(opts, legacyscheme.ParameterCodec)
-
-	exec, err := remotecommand.NewSPDYExecutor(h.r.ClientConfig, "POST", req.URL())
-	if err != nil {
-		return nil, err
-	}
-	err = exec.Stream(remotecommand.StreamOptions{
-		Stdin: r,
-	})
-	if err != nil {
+	if err := h.r.Transport.Upload(h.r.ClientConfig, build.Namespace, buildPodName, opts.Container, r); err != nil {
 		return nil, errors.NewInternalError(err)
 	}
 	cancel = false
@@ -0,0 +1,87 @@
+package buildconfiginstantiate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	buildapi "github.com/openshift/origin/pkg/build/apis/build"
+)
+
+func TestInstantiateDedupeKeyIsStableAndDistinguishesRequests(t *testing.T) {
+	a := &buildapi.BuildRequest{
+		Namespace: "ns",
+		Name:      "bc",
+		Revision:  &buildapi.SourceRevision{Git: &buildapi.GitSourceRevision{Commit: "abc123"}},
+	}
+	b := &buildapi.BuildRequest{
+		Namespace: "ns",
+		Name:      "bc",
+		Revision:  &buildapi.SourceRevision{Git: &buildapi.GitSourceRevision{Commit: "abc123"}},
+	}
+	c := &buildapi.BuildRequest{
+		Namespace: "ns",
+		Name:      "bc",
+		Revision:  &buildapi.SourceRevision{Git: &buildapi.GitSourceRevision{Commit: "def456"}},
+	}
+
+	if instantiateDedupeKey(a) != instantiateDedupeKey(b) {
+		t.Fatal("expected identical requests to dedupe to the same key")
+	}
+	if instantiateDedupeKey(a) == instantiateDedupeKey(c) {
+		t.Fatal("expected requests with different commits to have different dedupe keys")
+	}
+}
+
+func TestReapPendingLockedDropsOnlyExpiredEntries(t *testing.T) {
+	s := &InstantiateREST{pending: map[string]*pendingInstantiation{}}
+
+	fresh := &pendingInstantiation{key: "fresh", created: time.Now()}
+	stale := &pendingInstantiation{key: "stale", created: time.Now().Add(-(pendingInstantiationTTL + time.Minute))}
+	s.pending[fresh.key] = fresh
+	s.pending[stale.key] = stale
+
+	s.reapPendingLocked()
+
+	if _, ok := s.pending["fresh"]; !ok {
+		t.Fatal("expected the fresh entry to survive reaping")
+	}
+	if _, ok := s.pending["stale"]; ok {
+		t.Fatal("expected the stale entry to be reaped")
+	}
+}
+
+func TestPendingInstantiationAccessorsAreSynchronized(t *testing.T) {
+	s := &InstantiateREST{pending: map[string]*pendingInstantiation{}}
+	p := &pendingInstantiation{key: "k", token: "t", created: time.Now()}
+	s.pending[p.key] = p
+
+	if got := s.pendingBuildName(p); got != "" {
+		t.Fatalf("pendingBuildName() = %q, want empty before it's set", got)
+	}
+	s.setPendingBuildName(p, "build-1")
+	if got := s.pendingBuildName(p); got != "build-1" {
+		t.Fatalf("pendingBuildName() = %q, want %q", got, "build-1")
+	}
+
+	if got := s.pendingErr(p); got != nil {
+		t.Fatalf("pendingErr() = %v, want nil before it's set", got)
+	}
+	wantErr := errors.New("instantiate failed")
+	s.setPendingErr(p, wantErr)
+	if got := s.pendingErr(p); got != wantErr {
+		t.Fatalf("pendingErr() = %v, want %v", got, wantErr)
+	}
+
+	if got := s.findPendingByToken("t"); got != p {
+		t.Fatalf("findPendingByToken(%q) = %v, want %v", "t", got, p)
+	}
+	if got := s.findPendingByToken("missing"); got != nil {
+		t.Fatalf("findPendingByToken(missing) = %v, want nil", got)
+	}
+
+	s.forgetPending(p.key)
+	if _, ok := s.pending[p.key]; ok {
+		t.Fatal("expected forgetPending to remove the entry")
+	}
+}
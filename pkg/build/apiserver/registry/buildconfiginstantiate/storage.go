@@ -0,0 +1,27 @@
+package buildconfiginstantiate
+
+import (
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// NewBuildSubresourceStorage returns the `rest.Storage` map entries this
+// package contributes to the Build resource's subresource map, keyed by the
+// subresource name relative to the Build resource ("cancel" for
+// builds/cancel). The build apiserver wires this map in
+// alongside the Build resource's primary storage.
+func NewBuildSubresourceStorage(cancel *BuildCancelREST) map[string]rest.Storage {
+	return map[string]rest.Storage{
+		"cancel": cancel,
+	}
+}
+
+// NewBuildConfigSubresourceStorage returns the `rest.Storage` map entries
+// this package contributes to the BuildConfig resource's subresource map,
+// alongside the existing "instantiate" and "instantiatebinary" entries the
+// build apiserver already installs.
+func NewBuildConfigSubresourceStorage(progress *BuildInstantiateProgressREST, binaryStatus *BinaryInstantiateStatusREST) map[string]rest.Storage {
+	return map[string]rest.Storage{
+		"instantiate/progress":     progress,
+		"instantiatebinary/status": binaryStatus,
+	}
+}
@@ -0,0 +1,51 @@
+package buildconfiginstantiate
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/storage/names"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+
+	buildapi "github.com/openshift/origin/pkg/build/apis/build"
+	buildvalidation "github.com/openshift/origin/pkg/build/apis/build/validation"
+)
+
+// binaryStrategy is the rest.RESTCreateStrategy BinaryInstantiateREST runs
+// BinaryBuildRequestOptions through before using them, the same way every
+// other Create path validates its input object before acting on it.
+type binaryStrategy struct {
+	runtime.ObjectTyper
+	names.NameGenerator
+}
+
+// BinaryStrategy validates BinaryBuildRequestOptions on the instantiatebinary
+// connector.
+var BinaryStrategy = binaryStrategy{legacyscheme.Scheme, names.SimpleNameGenerator}
+
+func (binaryStrategy) NamespaceScoped() bool { return true }
+
+func (binaryStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {}
+
+func (binaryStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	options := obj.(*buildapi.BinaryBuildRequestOptions)
+	return buildvalidation.ValidateBinaryBuildRequestOptions(options)
+}
+
+func (binaryStrategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	return nil
+}
+
+func (binaryStrategy) Canonicalize(obj runtime.Object) {}
+
+func init() {
+	// BinaryBuildRequestOptions has to be a known type before the parameter
+	// codec can decode instantiatebinary's query string into it, the same
+	// reason buildInstantiateStatus is registered in rest.go's init.
+	legacyscheme.Scheme.AddKnownTypeWithName(
+		schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "BinaryBuildRequestOptions"},
+		&buildapi.BinaryBuildRequestOptions{},
+	)
+}
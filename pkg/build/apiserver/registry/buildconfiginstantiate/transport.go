@@ -0,0 +1,152 @@
+package buildconfiginstantiate
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/net/websocket"
+
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// UploadTransport streams a binary build archive into the named container
+// of a running build pod. SPDY pod-attach is the default, but SPDY is
+// deprecated in the Kubernetes ecosystem and some proxies throttle or break
+// long-lived SPDY upgrades, so alternate transports can be selected on
+// BinaryInstantiateREST instead.
+type UploadTransport interface {
+	// Upload streams r to the named container of the named pod and blocks
+	// until the transfer completes or fails.
+	Upload(clientConfig *restclient.Config, namespace, podName, container string, r io.Reader) error
+}
+
+func attachURL(clientConfig *restclient.Config, namespace, podName, container string) (*restclient.Request, error) {
+	restClient, err := restclient.RESTClientFor(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	opts := &kapi.PodAttachOptions{Stdin: true, Container: container}
+	req := restClient.Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("attach")
+	req.VersionedParams(opts, legacyscheme.ParameterCodec)
+	return req, nil
+}
+
+// SPDYUploadTransport attaches to the build pod over SPDY, the historical
+// and still-default transport.
+type SPDYUploadTransport struct{}
+
+func (SPDYUploadTransport) Upload(clientConfig *restclient.Config, namespace, podName, container string, r io.Reader) error {
+	req, err := attachURL(clientConfig, namespace, podName, container)
+	if err != nil {
+		return err
+	}
+	exec, err := remotecommand.NewSPDYExecutor(clientConfig, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return exec.Stream(remotecommand.StreamOptions{Stdin: r})
+}
+
+// stdinChannel is the channel index the Kubernetes pod-attach streaming
+// protocol (channel.k8s.io / base64.channel.k8s.io) assigns to stdin. Every
+// message on the attach socket is multiplexed over a single connection by
+// prefixing it with one of these channel bytes; writing raw unframed bytes
+// is indistinguishable from a malformed stream to a real kubelet.
+const stdinChannel = 0
+
+// channelWriter frames each Write as one channel.k8s.io message, prefixed
+// with the stdin channel byte, so io.Copy's chunking produces a sequence of
+// properly framed messages instead of a single raw byte stream. send is
+// factored out as a func so the framing logic can be unit tested without a
+// live websocket connection.
+type channelWriter struct {
+	send func(p []byte) error
+}
+
+func (w channelWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p)+1)
+	buf[0] = stdinChannel
+	copy(buf[1:], p)
+	if err := w.send(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WebSocketUploadTransport attaches to the build pod over a WebSocket
+// upgrade instead of SPDY, for front-ends that throttle or reject SPDY. It
+// speaks the same channel.k8s.io framing a real kubelet attach endpoint
+// expects. The dial does not apply clientConfig's TLS settings or
+// credentials; only the attach URL is taken from clientConfig.
+type WebSocketUploadTransport struct{}
+
+func (WebSocketUploadTransport) Upload(clientConfig *restclient.Config, namespace, podName, container string, r io.Reader) error {
+	req, err := attachURL(clientConfig, namespace, podName, container)
+	if err != nil {
+		return err
+	}
+	url := req.URL()
+	switch url.Scheme {
+	case "https":
+		url.Scheme = "wss"
+	default:
+		url.Scheme = "ws"
+	}
+
+	config, err := websocket.NewConfig(url.String(), "http://localhost")
+	if err != nil {
+		return err
+	}
+	config.Header.Set("Origin", "http://localhost")
+	// channel.k8s.io multiplexes stdin/stdout/stderr/error as raw bytes
+	// prefixed with a channel index; base64.channel.k8s.io base64-encodes
+	// each frame instead for intermediaries that can't pass binary frames.
+	// Raw framing is cheaper and every target we attach to can pass binary
+	// websocket frames, so that's the only subprotocol offered.
+	config.Protocol = []string{"channel.k8s.io"}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return err
+	}
+	defer ws.Close()
+	cw := channelWriter{send: func(p []byte) error { return websocket.Message.Send(ws, p) }}
+	_, err = io.Copy(cw, r)
+	return err
+}
+
+// HTTP2UploadTransport is kept for callers that already select it by name,
+// but pod attach is a protocol-upgrade subresource (SPDY or the
+// channel.k8s.io websocket framing above), not an ordinary request/response
+// endpoint: a kubelet never reads a plain POST body as the attached
+// container's stdin, so there is no duplex HTTP/2 request that can make
+// this work against a real apiserver. Rather than silently appear to
+// succeed while the pod never receives any of r, Upload fails fast with an
+// error that says so instead of performing the non-functional POST.
+type HTTP2UploadTransport struct{}
+
+func (HTTP2UploadTransport) Upload(clientConfig *restclient.Config, namespace, podName, container string, r io.Reader) error {
+	return fmt.Errorf("upload to pod %s/%s container %s: HTTP2UploadTransport is not a functional attach transport; pod attach requires a SPDY or websocket protocol upgrade, use SPDYUploadTransport or WebSocketUploadTransport instead", namespace, podName, container)
+}
+
+// fakeUploadTransport is an UploadTransport that never touches a real pod;
+// it exists so the wait/cancel state machine in binaryInstantiateHandler can
+// be exercised without a live apiserver and kubelet.
+type fakeUploadTransport struct {
+	err error
+}
+
+func (f fakeUploadTransport) Upload(clientConfig *restclient.Config, namespace, podName, container string, r io.Reader) error {
+	if f.err != nil {
+		return f.err
+	}
+	_, err := io.Copy(ioutil.Discard, r)
+	return err
+}
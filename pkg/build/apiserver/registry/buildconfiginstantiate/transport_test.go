@@ -0,0 +1,56 @@
+package buildconfiginstantiate
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestFakeUploadTransportUpload(t *testing.T) {
+	archive := []byte("fake build context archive")
+
+	ft := fakeUploadTransport{}
+	if err := ft.Upload(nil, "ns", "pod", "container", bytes.NewReader(archive)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantErr := errors.New("boom")
+	ft = fakeUploadTransport{err: wantErr}
+	if err := ft.Upload(nil, "ns", "pod", "container", bytes.NewReader(archive)); err != wantErr {
+		t.Fatalf("got error %v, want %v", err, wantErr)
+	}
+}
+
+func TestHTTP2UploadTransportNotFunctional(t *testing.T) {
+	tr := HTTP2UploadTransport{}
+	if err := tr.Upload(nil, "ns", "pod", "container", bytes.NewReader(nil)); err == nil {
+		t.Fatal("expected an error since HTTP2UploadTransport cannot perform the pod attach protocol upgrade")
+	}
+}
+
+func TestChannelWriterFramesEachWriteWithStdinChannel(t *testing.T) {
+	var sent [][]byte
+	send := func(p []byte) error {
+		buf := append([]byte(nil), p...)
+		sent = append(sent, buf)
+		return nil
+	}
+
+	w := channelWriter{send: send}
+	n, err := w.Write([]byte("hello"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != len("hello") {
+		t.Fatalf("Write returned %d, want %d", n, len("hello"))
+	}
+	if len(sent) != 1 {
+		t.Fatalf("got %d messages, want 1", len(sent))
+	}
+	if sent[0][0] != stdinChannel {
+		t.Fatalf("frame channel byte = %d, want %d", sent[0][0], stdinChannel)
+	}
+	if got := string(sent[0][1:]); got != "hello" {
+		t.Fatalf("frame payload = %q, want %q", got, "hello")
+	}
+}
@@ -0,0 +1,143 @@
+package buildconfiginstantiate
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// uploadSessionTTL bounds how long an upload session (and its backing temp
+// file) is kept if the client disappears mid-upload, crashes, or simply
+// never sends a terminal chunk. Without this an abandoned upload leaks a
+// temp file and a map entry for the life of the apiserver process.
+const uploadSessionTTL = 30 * time.Minute
+
+// uploadSession tracks the state of a single resumable binary upload. Bytes
+// are buffered in a temp file on disk as chunks arrive so a dropped
+// connection only costs the client the last unacknowledged chunk instead of
+// the whole build context.
+type uploadSession struct {
+	ID       string
+	Received int64
+
+	created time.Time
+
+	mu   sync.Mutex
+	hash hash.Hash
+	file *os.File
+}
+
+func newUploadSession() (*uploadSession, error) {
+	f, err := ioutil.TempFile("", "build-upload-")
+	if err != nil {
+		return nil, err
+	}
+	return &uploadSession{
+		ID:      string(uuid.NewUUID()),
+		created: time.Now(),
+		hash:    sha256.New(),
+		file:    f,
+	}, nil
+}
+
+// Write appends a chunk at the given offset. Chunks are expected to arrive
+// in order; an offset that doesn't match what has already been received is
+// rejected so the client can re-synchronize with Checksum()/Received.
+func (s *uploadSession) Write(offset int64, p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if offset != s.Received {
+		return 0, fmt.Errorf("upload session %s expected offset %d, got %d", s.ID, s.Received, offset)
+	}
+	n, err := s.file.Write(p)
+	s.hash.Write(p[:n])
+	s.Received += int64(n)
+	return n, err
+}
+
+// Checksum returns the hex-encoded sha256 of the bytes received so far.
+func (s *uploadSession) Checksum() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("%x", s.hash.Sum(nil))
+}
+
+// Reader returns a reader over the assembled archive from the beginning,
+// for use once the client has sent the terminal chunk.
+func (s *uploadSession) Reader() (io.ReadCloser, error) {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return s.file, nil
+}
+
+// Close releases the session's backing temp file.
+func (s *uploadSession) Close() error {
+	name := s.file.Name()
+	err := s.file.Close()
+	os.Remove(name)
+	return err
+}
+
+// uploadSessionRegistry is a small in-memory store of in-flight resumable
+// uploads, keyed by session ID. It is intentionally process-local: a restart
+// of the apiserver requires the client to start a new upload session.
+type uploadSessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*uploadSession
+}
+
+func newUploadSessionRegistry() *uploadSessionRegistry {
+	return &uploadSessionRegistry{sessions: map[string]*uploadSession{}}
+}
+
+// New allocates and registers a fresh upload session, reaping any sessions
+// that have aged past uploadSessionTTL along the way.
+func (r *uploadSessionRegistry) New() (*uploadSession, error) {
+	s, err := newUploadSession()
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reapLocked()
+	r.sessions[s.ID] = s
+	return s, nil
+}
+
+// reapLocked closes and forgets sessions older than uploadSessionTTL. Called
+// with r.mu already held.
+func (r *uploadSessionRegistry) reapLocked() {
+	now := time.Now()
+	for id, s := range r.sessions {
+		if now.Sub(s.created) > uploadSessionTTL {
+			s.Close()
+			delete(r.sessions, id)
+		}
+	}
+}
+
+// Get looks up a previously registered session by ID.
+func (r *uploadSessionRegistry) Get(id string) (*uploadSession, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	return s, ok
+}
+
+// Delete closes and forgets a session, freeing its temp file.
+func (r *uploadSessionRegistry) Delete(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.sessions[id]; ok {
+		s.Close()
+		delete(r.sessions, id)
+	}
+}
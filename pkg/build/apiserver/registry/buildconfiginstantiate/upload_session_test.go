@@ -0,0 +1,98 @@
+package buildconfiginstantiate
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestUploadSessionWriteRejectsOutOfOrderOffset(t *testing.T) {
+	s, err := newUploadSession()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.Write(0, []byte("hello")); err != nil {
+		t.Fatalf("unexpected error on first chunk: %v", err)
+	}
+	if _, err := s.Write(0, []byte("hello")); err == nil {
+		t.Fatal("expected an error writing a chunk at an already-received offset")
+	}
+	if _, err := s.Write(100, []byte("world")); err == nil {
+		t.Fatal("expected an error writing a chunk at an offset past what's been received")
+	}
+	if _, err := s.Write(5, []byte(" world")); err != nil {
+		t.Fatalf("unexpected error continuing at the correct offset: %v", err)
+	}
+}
+
+func TestUploadSessionChecksumAndReader(t *testing.T) {
+	s, err := newUploadSession()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.Write(0, []byte("hello world")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Received != int64(len("hello world")) {
+		t.Fatalf("Received = %d, want %d", s.Received, len("hello world"))
+	}
+	if got := s.Checksum(); len(got) != 64 {
+		t.Fatalf("Checksum() = %q, want a 64-character hex sha256 digest", got)
+	}
+
+	r, err := s.Reader()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Close()
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != "hello world" {
+		t.Fatalf("Reader() content = %q, want %q", body, "hello world")
+	}
+}
+
+func TestUploadSessionRegistryReapsExpiredSessions(t *testing.T) {
+	r := newUploadSessionRegistry()
+
+	s, err := r.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.created = time.Now().Add(-(uploadSessionTTL + time.Minute))
+
+	if _, err := r.New(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := r.Get(s.ID); ok {
+		t.Fatal("expected the expired session to have been reaped")
+	}
+	if len(r.sessions) != 1 {
+		t.Fatalf("got %d sessions registered, want 1 (only the fresh one)", len(r.sessions))
+	}
+}
+
+func TestUploadSessionRegistryGetAndDelete(t *testing.T) {
+	r := newUploadSessionRegistry()
+
+	s, err := r.New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, ok := r.Get(s.ID); !ok || got != s {
+		t.Fatalf("Get(%q) = %v, %v; want %v, true", s.ID, got, ok, s)
+	}
+
+	r.Delete(s.ID)
+	if _, ok := r.Get(s.ID); ok {
+		t.Fatal("expected session to be gone after Delete")
+	}
+}